@@ -0,0 +1,40 @@
+// Command csi-proxy is the csi-proxy server entrypoint. It selects an
+// internal/os/backend.Backend and serves the gRPC API groups over it.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/backend"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/linuxloop"
+)
+
+func main() {
+	osBackend := flag.String("os-backend", string(backend.Windows),
+		fmt.Sprintf("which OS backend to serve the API groups with (%q or %q)", backend.Windows, backend.LinuxLoop))
+	osBackendRoot := flag.String("os-backend-root", "",
+		fmt.Sprintf("root directory the %q backend confines paths to (default: its own temp directory); ignored by %q", backend.LinuxLoop, backend.Windows))
+	flag.Parse()
+
+	b, err := selectBackend(backend.Name(*osBackend), *osBackendRoot)
+	if err != nil {
+		log.Fatalf("failed to select os-backend %q: %v", *osBackend, err)
+	}
+
+	if err := run(b); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// selectBackend resolves name to a backend.Backend, honoring root when name
+// is backend.LinuxLoop so an operator can point it somewhere other than its
+// default temp directory; every other backend ignores root and is resolved
+// through the normal backend.Get registry.
+func selectBackend(name backend.Name, root string) (backend.Backend, error) {
+	if name == backend.LinuxLoop && root != "" {
+		return linuxloop.New(root), nil
+	}
+	return backend.Get(name)
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"log"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/backend"
+)
+
+// run starts serving the csi-proxy API groups against b.
+//
+// NOTE: this tree has no client/api/*/v1 proto definitions and no
+// google.golang.org/grpc dependency, so the internal/server/* handlers
+// (filesystem, smb and volume; there is no disk server) are not registered
+// here, and run only logs which implementations b selected. Once those
+// exist, this is where each group's gRPC server would be constructed from b
+// and registered on a grpc.Server listening on the named pipe / unix socket
+// csi-proxy clients dial.
+func run(b backend.Backend) error {
+	log.Printf("os-backend ready: filesystem=%T smb=%T volume=%T disk=%T",
+		b.Filesystem(), b.SMB(), b.Volume(), b.Disk())
+	return nil
+}
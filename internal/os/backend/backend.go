@@ -0,0 +1,62 @@
+// Package backend lets a csi-proxy server select which concrete OS API
+// implementations it runs against. Exactly one Backend is chosen at server
+// start via the --os-backend flag:
+//
+//   - windows (default): the real implementations in internal/os/filesystem,
+//     internal/os/smb and internal/os/volume, backed by Win32 calls.
+//   - linuxloop: internal/os/linuxloop, which implements the same surface
+//     against a chroot-like root directory and loopback files, so the full
+//     gRPC server and its integration tests can run on a contributor's Linux
+//     machine without a Windows host. This mirrors how the host-path CSI
+//     driver stands in for a real storage backend during development.
+//
+// A backend registers itself from an init() func, so selecting one is just a
+// matter of importing its package for the side effect, the same pattern
+// Go's database/sql drivers use.
+package backend
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/disk"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/filesystem"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/smb"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/volume"
+)
+
+// Name identifies a registered OS backend implementation.
+type Name string
+
+const (
+	// Windows is the default, real implementation backed by Win32 calls.
+	Windows Name = "windows"
+	// LinuxLoop implements the same surface against a root directory and
+	// loopback files, for development and CI on Linux.
+	LinuxLoop Name = "linuxloop"
+)
+
+// Backend bundles together the concrete OS API implementations used to
+// satisfy a single csi-proxy server instance.
+type Backend interface {
+	Filesystem() filesystem.API
+	SMB() smb.API
+	Volume() volume.API
+	Disk() disk.API
+}
+
+var registry = map[Name]func() Backend{}
+
+// Register adds a backend constructor under name. It is expected to be
+// called from the registering package's init() func.
+func Register(name Name, factory func() Backend) {
+	registry[name] = factory
+}
+
+// Get constructs the backend registered under name.
+func Get(name Name) (Backend, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("no os backend registered for %q", name)
+	}
+	return factory(), nil
+}
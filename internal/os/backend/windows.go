@@ -0,0 +1,20 @@
+package backend
+
+import (
+	"github.com/kubernetes-csi/csi-proxy/internal/os/disk"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/filesystem"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/smb"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/volume"
+)
+
+func init() {
+	Register(Windows, func() Backend { return windowsBackend{} })
+}
+
+// windowsBackend wires the real, Win32-backed implementations together.
+type windowsBackend struct{}
+
+func (windowsBackend) Filesystem() filesystem.API { return filesystem.New() }
+func (windowsBackend) SMB() smb.API               { return smb.New() }
+func (windowsBackend) Volume() volume.API         { return volume.New() }
+func (windowsBackend) Disk() disk.API             { return disk.New() }
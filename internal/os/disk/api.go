@@ -0,0 +1,20 @@
+package disk
+
+// Implements the Disk OS API calls. All code here should be very simple
+// pass-through to the OS APIs. Any logic around the APIs should go in
+// internal/server/disk/server.go so that logic can be easily unit-tested
+// without requiring specific OS environments.
+//
+// The platform-neutral API surface lives in this file so that it can be
+// imported and type-checked on any GOOS (e.g. by internal/os/linuxloop and
+// internal/os/backend). The concrete implementations live in api_windows.go
+// (build tag windows) and api_unsupported.go (every other GOOS).
+
+// API is the exposed Disk API
+type API interface {
+	ListDiskIDs() (map[string]string, error)
+	PartitionDisk(diskID string) error
+	Rescan() error
+	GetAttachState(diskID string) (bool, error)
+	SetAttachState(diskID string, online bool) error
+}
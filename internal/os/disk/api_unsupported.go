@@ -0,0 +1,27 @@
+//go:build !windows
+
+package disk
+
+import "errors"
+
+// errUnsupported is returned by every method of diskAPI; it exists so the
+// package (and anything that imports its platform-neutral API type, such as
+// internal/os/backend) still builds on non-Windows GOOS values. The real
+// implementation lives in api_windows.go; internal/os/linuxloop provides the
+// non-Windows development backend.
+var errUnsupported = errors.New("disk: not supported on this platform")
+
+type diskAPI struct{}
+
+// check that diskAPI implements API
+var _ API = &diskAPI{}
+
+func New() API {
+	return diskAPI{}
+}
+
+func (diskAPI) ListDiskIDs() (map[string]string, error)         { return nil, errUnsupported }
+func (diskAPI) PartitionDisk(diskID string) error               { return errUnsupported }
+func (diskAPI) Rescan() error                                   { return errUnsupported }
+func (diskAPI) GetAttachState(diskID string) (bool, error)      { return false, errUnsupported }
+func (diskAPI) SetAttachState(diskID string, online bool) error { return errUnsupported }
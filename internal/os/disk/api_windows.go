@@ -0,0 +1,113 @@
+//go:build windows
+
+package disk
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type diskAPI struct{}
+
+// check that diskAPI implements API
+var _ API = &diskAPI{}
+
+func New() API {
+	return diskAPI{}
+}
+
+// ListDiskIDs enumerates the disks attached to the node, keyed by disk
+// number, with each value being the disk's unique ID as reported by
+// Get-Disk. This mirrors how the filesystem and volume APIs identify their
+// targets by a stable UniqueId rather than a drive letter, since drive
+// letters aren't assigned until a disk is online and partitioned.
+func (diskAPI) ListDiskIDs() (map[string]string, error) {
+	cmd := exec.Command("powershell", "/c",
+		`Get-Disk | ForEach-Object { "$($_.Number) $($_.UniqueId)" }`)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disks: %v, output: %s", err, string(output))
+	}
+
+	ids := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		ids[fields[0]] = fields[1]
+	}
+	return ids, nil
+}
+
+// PartitionDisk brings diskID online, initializes it with a GPT partition
+// table if it doesn't already have one, and creates a single partition
+// spanning the rest of the disk, mirroring the steps a CSI node plugin
+// needs before a disk can be formatted.
+func (diskAPI) PartitionDisk(diskID string) error {
+	cmd := exec.Command("powershell", "/c",
+		fmt.Sprintf(`
+$ErrorActionPreference = 'Stop'
+$disk = Get-Disk -UniqueId "%s"
+if ($disk.PartitionStyle -eq 'RAW') {
+    Initialize-Disk -UniqueId "%s" -PartitionStyle GPT
+}
+if ($disk.IsOffline) {
+    Set-Disk -UniqueId "%s" -IsOffline $false
+}
+$partitions = Get-Partition -DiskId $disk.DiskId -ErrorAction SilentlyContinue
+if (-not $partitions) {
+    New-Partition -DiskId $disk.DiskId -UseMaximumSize | Out-Null
+}
+`, diskID, diskID, diskID))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to partition disk %s: %v, output: %s", diskID, err, string(output))
+	}
+	return nil
+}
+
+// Rescan forces Windows to rescan the storage subsystem for newly attached
+// or removed disks, which is needed after a CSI volume has just been
+// attached to the node.
+func (diskAPI) Rescan() error {
+	cmd := exec.Command("powershell", "/c", `Update-HostStorageCache`)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to rescan disks: %v, output: %s", err, string(output))
+	}
+	return nil
+}
+
+// GetAttachState reports whether diskID is online (attached and usable), as
+// opposed to offline (visible to the OS but not yet brought online).
+func (diskAPI) GetAttachState(diskID string) (bool, error) {
+	cmd := exec.Command("powershell", "/c",
+		fmt.Sprintf(`(Get-Disk -UniqueId "%s").IsOffline`, diskID))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("failed to get attach state for disk %s: %v, output: %s", diskID, err, string(output))
+	}
+	isOffline, err := strconv.ParseBool(strings.TrimSpace(string(output)))
+	if err != nil {
+		return false, fmt.Errorf("failed to parse attach state for disk %s: %v", diskID, err)
+	}
+	return !isOffline, nil
+}
+
+// SetAttachState brings diskID online or offline.
+func (diskAPI) SetAttachState(diskID string, online bool) error {
+	cmd := exec.Command("powershell", "/c",
+		fmt.Sprintf(`Set-Disk -UniqueId "%s" -IsOffline %t`, diskID, !online))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to set attach state for disk %s: %v, output: %s", diskID, err, string(output))
+	}
+	return nil
+}
@@ -1,16 +1,24 @@
 package filesystem
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
 // Implements the Filesystem OS API calls. All code here should be very simple
 // pass-through to the OS APIs. Any logic around the APIs should go in
 // internal/server/filesystem/server.go so that logic can be easily unit-tested
 // without requiring specific OS environments.
+//
+// The platform-neutral API surface lives in this file so that it can be
+// imported and type-checked on any GOOS (e.g. by internal/os/linuxloop and
+// internal/os/backend). The concrete implementations live in api_windows.go
+// (build tag windows) and api_unsupported.go (every other GOOS).
 
 // API is the exposed Filesystem API
 type API interface {
@@ -19,96 +27,132 @@ type API interface {
 	Mkdir(path string) error
 	Rmdir(path string, force bool) error
 	LinkPath(tgt string, src string) error
+	LinkPathSubpath(volumeRoot string, subpath string, target string) error
 	IsMountPoint(path string) (bool, error)
+	GetMountPointType(path string) (MountType, error)
+	PathValidWithTimeout(ctx context.Context, path string, timeout time.Duration) (bool, error)
 }
 
-type filesystemAPI struct{}
-
-// check that filesystemAPI implements API
-var _ API = &filesystemAPI{}
-
-func New() API {
-	return filesystemAPI{}
-}
+// defaultPathValidTimeout bounds how long PathValid will wait on a path
+// probe, preserving its historical synchronous behavior for callers that
+// don't need finer control over cancellation.
+const defaultPathValidTimeout = 30 * time.Second
+
+// ErrPathUnreachable is returned by PathValidWithTimeout when the remote
+// server backing path could not be reached within the timeout, e.g. because
+// an SMB server is down or credentials were rotated. Callers should treat
+// this as "needs a remount", as distinct from ErrPathNotFound which means
+// the path genuinely doesn't exist.
+var ErrPathUnreachable = errors.New("path unreachable")
+
+// ErrPathNotFound is returned by PathValidWithTimeout when every element of
+// path was reachable but the path itself does not exist.
+var ErrPathNotFound = errors.New("path not found")
+
+// MountType describes what, if anything, is mounted at a given path.
+type MountType string
+
+const (
+	// MountTypeNone means nothing is mounted at the path, or the path does
+	// not exist.
+	MountTypeNone MountType = "None"
+	// MountTypeSymlink means the path is a plain symlink, not a reparse
+	// point created by the OS mount manager.
+	MountTypeSymlink MountType = "Symlink"
+	// MountTypeVolumeMountPoint means the path is a reparse point of type
+	// IO_REPARSE_TAG_MOUNT_POINT, i.e. a real Windows volume mount point.
+	MountTypeVolumeMountPoint MountType = "VolumeMountPoint"
+	// MountTypeSMBRemote means the path is a mounted SMB share.
+	MountTypeSMBRemote MountType = "SMBRemote"
+	// MountTypeCorrupted means the path is a reparse point or remote mount
+	// that can no longer be reached, e.g. a stale SMB mount whose server
+	// is unreachable or whose credentials have been rotated.
+	MountTypeCorrupted MountType = "Corrupted"
+	// MountTypeUnknown means the path is a reparse point of a type
+	// GetMountPointType doesn't recognize (e.g. deduplication, DFS-R, a
+	// cloud sync provider), so it cannot be classified as a mount point or
+	// a plain symlink.
+	MountTypeUnknown MountType = "Unknown"
+)
 
-func pathExists(path string) (bool, error) {
-	_, err := os.Lstat(path)
-	if err == nil {
-		return true, nil
-	}
-	if os.IsNotExist(err) {
+// classifyPathValid maps a PathValidWithTimeout result onto PathValid's
+// historical boolean-only, error-free-on-unreachable signature: an
+// unreachable remote server (ErrPathUnreachable) or a path that doesn't
+// exist (ErrPathNotFound) is swallowed into (false, nil) for backward
+// compatibility, while every other error is passed through unchanged.
+// Pulled out as its own function so the mapping can be unit-tested without
+// a real OS probe.
+func classifyPathValid(ok bool, err error) (bool, error) {
+	if errors.Is(err, ErrPathUnreachable) || errors.Is(err, ErrPathNotFound) {
 		return false, nil
 	}
-	return false, err
+	return ok, err
 }
 
-func (filesystemAPI) PathExists(path string) (bool, error) {
-	return pathExists(path)
-}
-
-func pathValid(path string) (bool, error) {
-	cmd := exec.Command("powershell", "/c", `Test-Path $Env:remotepath`)
-	cmd.Env = append(os.Environ(), fmt.Sprintf("remotepath=%s", path))
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return false, fmt.Errorf("returned output: %s, error: %v", string(output), err)
+// resolveSubpath validates that subpath is a relative path that, once joined
+// onto volumeRoot and with any symlinks resolved, still lexically resides
+// under volumeRoot. This is the same check kubelet relies on elsewhere to
+// implement volumeMounts.subPath safely: it must not allow a caller to escape
+// the volume root via an absolute path, ".." segments, or a symlink planted
+// inside the volume.
+//
+// This is pure path manipulation with no OS-specific calls, so it lives
+// here rather than in api_windows.go/api_unsupported.go, letting it be
+// unit-tested on any GOOS.
+func resolveSubpath(volumeRoot string, subpath string) (string, error) {
+	if filepath.IsAbs(subpath) {
+		return "", fmt.Errorf("subpath %q must be a relative path", subpath)
 	}
 
-	return strings.HasPrefix(strings.ToLower(string(output)), "true"), nil
-}
-
-// PathValid determines whether all elements of a path exist
-//   https://docs.microsoft.com/en-us/powershell/module/microsoft.powershell.management/test-path?view=powershell-7
-// for a remote path, determines whether connection is ok
-//   e.g. in a SMB server connection, if password is changed, connection will be lost, this func will return false
-func (filesystemAPI) PathValid(path string) (bool, error) {
-	return pathValid(path)
-}
-
-// Mkdir makes a dir with `os.MkdirAll`.
-func (filesystemAPI) Mkdir(path string) error {
-	return os.MkdirAll(path, 0755)
-}
-
-// Rmdir removes a dir with `os.Remove`, if force is true then `os.RemoveAll` is used instead.
-func (filesystemAPI) Rmdir(path string, force bool) error {
-	if force {
-		return os.RemoveAll(path)
+	joined := filepath.Join(volumeRoot, subpath)
+	cleanRoot := filepath.Clean(volumeRoot)
+	if joined != cleanRoot && !strings.HasPrefix(joined, cleanRoot+string(filepath.Separator)) {
+		return "", fmt.Errorf("subpath %q escapes volume root %q", subpath, volumeRoot)
 	}
-	return os.Remove(path)
-}
 
-// LinkPath creates newname as a symbolic link to oldname.
-func (filesystemAPI) LinkPath(oldname, newname string) error {
-	return os.Symlink(oldname, newname)
-}
+	resolved, err := filepath.EvalSymlinks(joined)
+	if os.IsNotExist(err) {
+		// The subpath may not exist yet (e.g. about to be created by the
+		// caller). Resolve its deepest existing ancestor instead, so a
+		// symlink planted along the way is still caught below.
+		existing, rest := joined, ""
+		for {
+			if _, lerr := os.Lstat(existing); !os.IsNotExist(lerr) || filepath.Dir(existing) == existing {
+				break
+			}
+			rest = filepath.Join(filepath.Base(existing), rest)
+			existing = filepath.Dir(existing)
+		}
+		resolved, err = filepath.EvalSymlinks(existing)
+		resolved = filepath.Join(resolved, rest)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve subpath %q: %v", subpath, err)
+	}
 
-// IsMountPoint - returns true if tgt is a mount point.
-// A path is considered a mount point if:
-//  - directory exists and
-//  - it is a soft link and
-//  - the target path of the link exists.
-func (filesystemAPI) IsMountPoint(tgt string) (bool, error) {
-	// This code is similar to k8s.io/kubernetes/pkg/util/mount except the pathExists usage.
-	// Also in a remote call environment the os error cannot be passed directly back, hence the callers
-	// are expected to perform the isExists check before calling this call in CSI proxy.
-	stat, err := os.Lstat(tgt)
+	resolvedRoot, err := filepath.EvalSymlinks(cleanRoot)
 	if err != nil {
-		return false, err
+		return "", fmt.Errorf("failed to resolve volume root %q: %v", volumeRoot, err)
 	}
 
-	// If its a link and it points to an existing file then its a mount point.
-	if stat.Mode()&os.ModeSymlink != 0 {
-		target, err := os.Readlink(tgt)
-		if err != nil {
-			return false, fmt.Errorf("readlink error: %v", err)
-		}
-		exists, err := pathExists(target)
-		if err != nil {
-			return false, err
-		}
-		return exists, nil
+	if resolved != resolvedRoot && !strings.HasPrefix(resolved, resolvedRoot+string(filepath.Separator)) {
+		return "", fmt.Errorf("subpath %q resolves outside of volume root %q", subpath, volumeRoot)
 	}
 
-	return false, nil
+	return joined, nil
+}
+
+// isRemoteSubstituteName reports whether a reparse target names a remote
+// share rather than a local path. Native mount points substitute a
+// "\??\Volume{GUID}\" device path for a local volume, or a path through the
+// Multiple UNC Provider — "\??\UNC\server\share" or "\Device\Mup\server\share"
+// — for an SMB global mapping or drive-letter-less UNC mount. A symlink
+// created by os.Symlink onto a UNC target instead carries the target
+// largely as given, e.g. "\\server\share\...".
+//
+// This is pure string matching with no OS-specific calls, so it lives here
+// rather than in api_windows.go, letting it be unit-tested on any GOOS.
+func isRemoteSubstituteName(substituteName string) bool {
+	upper := strings.ToUpper(substituteName)
+	return strings.Contains(upper, `\UNC\`) || strings.Contains(upper, `\DEVICE\MUP\`) || strings.HasPrefix(upper, `\\`)
 }
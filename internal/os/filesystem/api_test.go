@@ -0,0 +1,96 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSubpath(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "data"), 0755); err != nil {
+		t.Fatalf("failed to set up fixture: %v", err)
+	}
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatalf("failed to set up fixture: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		subpath string
+		wantErr bool
+	}{
+		{name: "plain relative subpath", subpath: "data", wantErr: false},
+		{name: "nested relative subpath that does not exist yet", subpath: "data/new", wantErr: false},
+		{name: "absolute path rejected", subpath: "/etc/passwd", wantErr: true},
+		{name: "dot-dot traversal rejected", subpath: "../escape", wantErr: true},
+		{name: "dot-dot traversal nested under a real dir rejected", subpath: "data/../../escape", wantErr: true},
+		{name: "symlink escape rejected", subpath: "escape", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveSubpath(root, tt.subpath)
+			if tt.wantErr && err == nil {
+				t.Fatalf("resolveSubpath(%q, %q) = nil error, want error", root, tt.subpath)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("resolveSubpath(%q, %q) = %v, want no error", root, tt.subpath, err)
+			}
+		})
+	}
+}
+
+func TestIsRemoteSubstituteName(t *testing.T) {
+	tests := []struct {
+		name           string
+		substituteName string
+		want           bool
+	}{
+		{name: "local volume device path", substituteName: `\??\Volume{11111111-1111-1111-1111-111111111111}\`, want: false},
+		{name: "MUP-routed UNC mount point", substituteName: `\??\UNC\server\share`, want: true},
+		{name: "Device Mup path", substituteName: `\Device\Mup\server\share`, want: true},
+		{name: "raw UNC symlink target", substituteName: `\\server\share\sub`, want: true},
+		{name: "empty substitute name", substituteName: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRemoteSubstituteName(tt.substituteName); got != tt.want {
+				t.Errorf("isRemoteSubstituteName(%q) = %v, want %v", tt.substituteName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyPathValid(t *testing.T) {
+	otherErr := errors.New("boom")
+
+	tests := []struct {
+		name    string
+		ok      bool
+		err     error
+		wantOK  bool
+		wantErr error
+	}{
+		{name: "reachable", ok: true, err: nil, wantOK: true, wantErr: nil},
+		{name: "unreachable is swallowed for backward compatibility", ok: false, err: ErrPathUnreachable, wantOK: false, wantErr: nil},
+		{name: "wrapped unreachable is still swallowed", ok: false, err: fmt.Errorf("probe failed: %w", ErrPathUnreachable), wantOK: false, wantErr: nil},
+		{name: "other errors pass through", ok: false, err: otherErr, wantOK: false, wantErr: otherErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOK, gotErr := classifyPathValid(tt.ok, tt.err)
+			if gotOK != tt.wantOK {
+				t.Errorf("classifyPathValid(%v, %v) ok = %v, want %v", tt.ok, tt.err, gotOK, tt.wantOK)
+			}
+			if !errors.Is(gotErr, tt.wantErr) && gotErr != tt.wantErr {
+				t.Errorf("classifyPathValid(%v, %v) err = %v, want %v", tt.ok, tt.err, gotErr, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+//go:build !windows
+
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// errUnsupported is returned by every method of filesystemAPI; it exists so
+// the package (and anything that imports its platform-neutral API type,
+// such as internal/os/backend) still builds on non-Windows GOOS values. The
+// real implementation lives in api_windows.go; internal/os/linuxloop
+// provides the non-Windows development backend.
+var errUnsupported = errors.New("filesystem: not supported on this platform")
+
+type filesystemAPI struct{}
+
+// check that filesystemAPI implements API
+var _ API = &filesystemAPI{}
+
+func New() API {
+	return filesystemAPI{}
+}
+
+func (filesystemAPI) PathExists(path string) (bool, error)  { return false, errUnsupported }
+func (filesystemAPI) PathValid(path string) (bool, error)   { return false, errUnsupported }
+func (filesystemAPI) Mkdir(path string) error               { return errUnsupported }
+func (filesystemAPI) Rmdir(path string, force bool) error   { return errUnsupported }
+func (filesystemAPI) LinkPath(tgt string, src string) error { return errUnsupported }
+func (filesystemAPI) LinkPathSubpath(volumeRoot string, subpath string, target string) error {
+	return errUnsupported
+}
+func (filesystemAPI) IsMountPoint(path string) (bool, error) { return false, errUnsupported }
+func (filesystemAPI) GetMountPointType(path string) (MountType, error) {
+	return MountTypeNone, errUnsupported
+}
+func (filesystemAPI) PathValidWithTimeout(ctx context.Context, path string, timeout time.Duration) (bool, error) {
+	return false, errUnsupported
+}
@@ -0,0 +1,356 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+type filesystemAPI struct{}
+
+// check that filesystemAPI implements API
+var _ API = &filesystemAPI{}
+
+func New() API {
+	return filesystemAPI{}
+}
+
+func pathExists(path string) (bool, error) {
+	_, err := os.Lstat(path)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (filesystemAPI) PathExists(path string) (bool, error) {
+	return pathExists(path)
+}
+
+// pathValidProbe opens path with FILE_FLAG_OPEN_REPARSE_POINT so it never
+// follows the final reparse point onto a remote share, and classifies the
+// result: a plain ERROR_FILE_NOT_FOUND/ERROR_PATH_NOT_FOUND means the path
+// doesn't exist, while ERROR_BAD_NETPATH, ERROR_HOST_UNREACHABLE or a
+// WSAETIMEDOUT-equivalent timeout mean the remote server can't be reached.
+func pathValidProbe(ctx context.Context, path string) error {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		handle, err := windows.CreateFile(
+			pathPtr,
+			0,
+			windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE,
+			nil,
+			windows.OPEN_EXISTING,
+			windows.FILE_FLAG_OPEN_REPARSE_POINT|windows.FILE_FLAG_BACKUP_SEMANTICS,
+			0,
+		)
+		if err != nil {
+			switch err {
+			case windows.ERROR_FILE_NOT_FOUND, windows.ERROR_PATH_NOT_FOUND:
+				done <- ErrPathNotFound
+			case windows.ERROR_BAD_NETPATH, windows.ERROR_HOST_UNREACHABLE, windows.WSAETIMEDOUT:
+				done <- ErrPathUnreachable
+			default:
+				done <- err
+			}
+			return
+		}
+		windows.CloseHandle(handle)
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", ErrPathUnreachable, ctx.Err())
+	}
+}
+
+// PathValidWithTimeout determines whether all elements of path exist,
+// bounding the underlying probe by timeout and ctx cancellation. For a
+// remote (e.g. SMB) path it distinguishes an unreachable server
+// (ErrPathUnreachable) from a path that genuinely doesn't exist
+// (ErrPathNotFound), so callers can decide whether to remount or recreate.
+func (filesystemAPI) PathValidWithTimeout(ctx context.Context, path string, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := pathValidProbe(ctx, path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PathValid determines whether all elements of a path exist; for a remote
+// path it also determines whether the connection is still usable, e.g. in
+// an SMB connection, if the password is changed the connection is lost and
+// this func will return false. It preserves the historical boolean-only,
+// error-free-on-unreachable signature; callers that need to distinguish
+// "not found" from "unreachable" should use PathValidWithTimeout directly.
+func (f filesystemAPI) PathValid(path string) (bool, error) {
+	ok, err := f.PathValidWithTimeout(context.Background(), path, defaultPathValidTimeout)
+	return classifyPathValid(ok, err)
+}
+
+// Mkdir makes a dir with `os.MkdirAll`.
+func (filesystemAPI) Mkdir(path string) error {
+	return os.MkdirAll(path, 0755)
+}
+
+// Rmdir removes a dir with `os.Remove`, if force is true then `os.RemoveAll` is used instead.
+func (filesystemAPI) Rmdir(path string, force bool) error {
+	if force {
+		return os.RemoveAll(path)
+	}
+	return os.Remove(path)
+}
+
+// LinkPath creates newname as a symbolic link to oldname.
+func (filesystemAPI) LinkPath(oldname, newname string) error {
+	return os.Symlink(oldname, newname)
+}
+
+// LinkPathSubpath creates target as a symbolic link to the directory at
+// subpath, a path relative to volumeRoot. subpath is resolved and validated
+// before the link is created so that an absolute path, a ".." traversal, or
+// a symlink planted inside the volume cannot be used to escape volumeRoot.
+// This allows CSI drivers to implement volumeMounts.subPath-style publishing
+// without exposing arbitrary host paths.
+func (filesystemAPI) LinkPathSubpath(volumeRoot string, subpath string, target string) error {
+	resolved, err := resolveSubpath(volumeRoot, subpath)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(resolved, target)
+}
+
+// IsMountPoint - returns true if tgt is a mount point.
+// A path is considered a mount point if:
+//   - directory exists and
+//   - it is a soft link and
+//   - the target path of the link exists.
+func (filesystemAPI) IsMountPoint(tgt string) (bool, error) {
+	// This code is similar to k8s.io/kubernetes/pkg/util/mount except the pathExists usage.
+	// Also in a remote call environment the os error cannot be passed directly back, hence the callers
+	// are expected to perform the isExists check before calling this call in CSI proxy.
+	stat, err := os.Lstat(tgt)
+	if err != nil {
+		return false, err
+	}
+
+	// If its a link and it points to an existing file then its a mount point.
+	if stat.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(tgt)
+		if err != nil {
+			return false, fmt.Errorf("readlink error: %v", err)
+		}
+		exists, err := pathExists(target)
+		if err != nil {
+			return false, err
+		}
+		return exists, nil
+	}
+
+	return false, nil
+}
+
+// Reparse tags not exposed by golang.org/x/sys/windows.
+// https://docs.microsoft.com/en-us/windows/win32/fileio/reparse-point-tags
+const (
+	ioReparseTagMountPoint = 0xA0000003
+	ioReparseTagSymlink    = 0xA000000C
+
+	// probeTimeout bounds how long GetMountPointType will wait for a CreateFile
+	// probe against a remote share before declaring it unreachable.
+	probeTimeout = 3 * time.Second
+)
+
+// GetMountPointType inspects path and classifies what is mounted there. It
+// distinguishes a plain symlink from a real Windows volume mount point
+// (reparse point of type IO_REPARSE_TAG_MOUNT_POINT), further classifying a
+// mount point whose target is a remote UNC path as MountTypeSMBRemote, and
+// probes remote targets with a short-timeout CreateFile to detect stale or
+// unreachable mounts (e.g. an SMB share whose server went away or whose
+// credentials were rotated) rather than reporting them as a healthy mount.
+func (filesystemAPI) GetMountPointType(path string) (MountType, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return MountTypeNone, err
+	}
+
+	attrs, err := windows.GetFileAttributes(pathPtr)
+	if err != nil {
+		if err == windows.ERROR_FILE_NOT_FOUND || err == windows.ERROR_PATH_NOT_FOUND {
+			return MountTypeNone, nil
+		}
+		return MountTypeNone, fmt.Errorf("GetFileAttributes(%s) failed: %v", path, err)
+	}
+
+	if attrs&windows.FILE_ATTRIBUTE_REPARSE_POINT == 0 {
+		return MountTypeNone, nil
+	}
+
+	tag, substituteName, err := getReparsePoint(pathPtr)
+	if err != nil {
+		return MountTypeNone, fmt.Errorf("failed to read reparse point for %s: %v", path, err)
+	}
+
+	switch tag {
+	case ioReparseTagMountPoint:
+		mountType := MountTypeVolumeMountPoint
+		if isRemoteSubstituteName(substituteName) {
+			mountType = MountTypeSMBRemote
+		}
+		if reachable := probePath(path); !reachable {
+			return MountTypeCorrupted, nil
+		}
+		return mountType, nil
+	case ioReparseTagSymlink:
+		// LinkPath/LinkPathSubpath bind a share in with os.Symlink, which
+		// creates an IO_REPARSE_TAG_SYMLINK, not a mount point — so this,
+		// not the case above, is the path csi-proxy's own SMB flow actually
+		// takes. A symlink whose target is a UNC path is just as much a
+		// remote mount as a native reparse point and needs the same
+		// reachability probe to detect a stale/corrupted share.
+		if isRemoteSubstituteName(substituteName) {
+			if reachable := probePath(path); !reachable {
+				return MountTypeCorrupted, nil
+			}
+			return MountTypeSMBRemote, nil
+		}
+		return MountTypeSymlink, nil
+	default:
+		// An unrecognized reparse tag (e.g. deduplication, DFS-R, a cloud
+		// sync provider) is neither a plain symlink nor one we know how to
+		// classify; mislabeling it MountTypeSymlink would tell a caller
+		// it's safe to treat like one.
+		return MountTypeUnknown, nil
+	}
+}
+
+// getReparsePoint opens path with FILE_FLAG_OPEN_REPARSE_POINT so the
+// reparse point itself (rather than its target) is opened, then issues
+// FSCTL_GET_REPARSE_POINT to read back the tag identifying what kind of
+// reparse point it is, along with its substitute name where present (e.g.
+// the device path a mount point redirects to).
+func getReparsePoint(pathPtr *uint16) (tag uint32, substituteName string, err error) {
+	handle, err := windows.CreateFile(
+		pathPtr,
+		0,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE,
+		nil,
+		windows.OPEN_EXISTING,
+		windows.FILE_FLAG_OPEN_REPARSE_POINT|windows.FILE_FLAG_BACKUP_SEMANTICS,
+		0,
+	)
+	if err != nil {
+		return 0, "", err
+	}
+	defer windows.CloseHandle(handle)
+
+	// REPARSE_DATA_BUFFER starts with ReparseTag (uint32), ReparseDataLength
+	// (uint16) and Reserved (uint16) before the type-specific payload, which
+	// for a mount point includes the full substitute path. The buffer must
+	// be sized to windows.MAXIMUM_REPARSE_DATA_BUFFER_SIZE the same way
+	// os.readReparseLink does; anything smaller makes DeviceIoControl fail
+	// with ERROR_MORE_DATA/ERROR_INSUFFICIENT_BUFFER on real mount points.
+	buf := make([]byte, windows.MAXIMUM_REPARSE_DATA_BUFFER_SIZE)
+	var bytesReturned uint32
+	err = windows.DeviceIoControl(handle, windows.FSCTL_GET_REPARSE_POINT, nil, 0, &buf[0], uint32(len(buf)), &bytesReturned, nil)
+	if err != nil {
+		return 0, "", err
+	}
+
+	tag = uint32(buf[0]) | uint32(buf[1])<<8 | uint32(buf[2])<<16 | uint32(buf[3])<<24
+
+	// Both MOUNT_POINT_REPARSE_BUFFER and SYMLINK_REPARSE_DATA_BUFFER start,
+	// after the common 8-byte reparse header, with SubstituteNameOffset,
+	// SubstituteNameLength, PrintNameOffset, PrintNameLength (all uint16);
+	// the symlink variant then has an extra 4-byte Flags field before the
+	// wide-char PathBuffer that the mount-point variant doesn't.
+	const reparseHeader = 8
+	var pathBufferStart int
+	switch tag {
+	case ioReparseTagMountPoint:
+		pathBufferStart = reparseHeader + 8
+	case ioReparseTagSymlink:
+		pathBufferStart = reparseHeader + 12
+	default:
+		return tag, "", nil
+	}
+
+	if len(buf) < reparseHeader+8 {
+		return tag, "", nil
+	}
+	substituteNameOffset := uint16(buf[reparseHeader]) | uint16(buf[reparseHeader+1])<<8
+	substituteNameLength := uint16(buf[reparseHeader+2]) | uint16(buf[reparseHeader+3])<<8
+
+	start := pathBufferStart + int(substituteNameOffset)
+	end := start + int(substituteNameLength)
+	if start < 0 || end > len(buf) || start > end {
+		return tag, "", nil
+	}
+
+	substituteName = windows.UTF16ToString(bytesToUTF16(buf[start:end]))
+	return tag, substituteName, nil
+}
+
+// bytesToUTF16 reinterprets a little-endian byte slice of even length as a
+// slice of uint16 code units.
+func bytesToUTF16(b []byte) []uint16 {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+	}
+	return u
+}
+
+// probePath attempts a bounded CreateFile against path to detect a stale or
+// unreachable remote mount (e.g. ERROR_HOST_UNREACHABLE, ERROR_BAD_NETPATH)
+// without blocking on the OS's much longer default network timeouts.
+func probePath(path string) bool {
+	done := make(chan bool, 1)
+	go func() {
+		pathPtr, err := windows.UTF16PtrFromString(path)
+		if err != nil {
+			done <- false
+			return
+		}
+		handle, err := windows.CreateFile(
+			pathPtr,
+			windows.GENERIC_READ,
+			windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE,
+			nil,
+			windows.OPEN_EXISTING,
+			windows.FILE_FLAG_BACKUP_SEMANTICS,
+			0,
+		)
+		if err != nil {
+			done <- false
+			return
+		}
+		windows.CloseHandle(handle)
+		done <- true
+	}()
+
+	select {
+	case reachable := <-done:
+		return reachable
+	case <-time.After(probeTimeout):
+		return false
+	}
+}
@@ -0,0 +1,132 @@
+package linuxloop
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/disk"
+)
+
+type diskAPI struct {
+	root string
+}
+
+var _ disk.API = diskAPI{}
+
+func newDiskAPI(root string) disk.API {
+	return diskAPI{root: root}
+}
+
+// ListDiskIDs lists the loop devices currently attached under root, keyed
+// by loop device name (e.g. "loop0"), with the backing file's confined
+// path as the ID, mirroring how a real disk's UniqueId is stable across
+// attach/detach cycles.
+func (d diskAPI) ListDiskIDs() (map[string]string, error) {
+	cmd := exec.Command("losetup", "-a")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loop devices: %v, output: %s", err, string(output))
+	}
+
+	ids := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		// losetup -a prints lines like "/dev/loop0: []: (/path/to/file)".
+		fields := strings.SplitN(line, ":", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		dev := strings.TrimPrefix(strings.TrimSpace(fields[0]), "/dev/")
+		if !strings.HasPrefix(dev, d.root) && !strings.Contains(line, d.root) {
+			continue
+		}
+		start, end := strings.Index(line, "("), strings.LastIndex(line, ")")
+		if start < 0 || end < start {
+			continue
+		}
+		ids[dev] = line[start+1 : end]
+	}
+	return ids, nil
+}
+
+// PartitionDisk attaches diskID's backing file (a path confined to root,
+// the same "loopback file instead of a physical disk" stand-in used by
+// FormatVolume) as a loop device and creates a single GPT partition
+// spanning it.
+func (d diskAPI) PartitionDisk(diskID string) error {
+	backingFile := confine(d.root, diskID)
+	loopDev, err := attachLoopDevice(backingFile)
+	if err != nil {
+		return fmt.Errorf("failed to attach loop device for disk %s: %v", diskID, err)
+	}
+
+	cmd := exec.Command("parted", "-s", loopDev, "mklabel", "gpt", "mkpart", "primary", "0%", "100%")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to partition disk %s: %v, output: %s", diskID, err, string(output))
+	}
+	return nil
+}
+
+// Rescan is a no-op on linuxloop: loop devices are attached explicitly by
+// PartitionDisk/SetAttachState rather than discovered by a bus rescan.
+func (diskAPI) Rescan() error {
+	return nil
+}
+
+// GetAttachState reports whether diskID's backing file currently has a loop
+// device attached to it.
+func (d diskAPI) GetAttachState(diskID string) (bool, error) {
+	backingFile := confine(d.root, diskID)
+	cmd := exec.Command("losetup", "-j", backingFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("failed to query loop device for disk %s: %v, output: %s", diskID, err, string(output))
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// SetAttachState attaches or detaches the loop device backing diskID.
+func (d diskAPI) SetAttachState(diskID string, online bool) error {
+	backingFile := confine(d.root, diskID)
+	if online {
+		_, err := attachLoopDevice(backingFile)
+		return err
+	}
+
+	cmd := exec.Command("losetup", "-j", backingFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to query loop device for disk %s: %v, output: %s", diskID, err, string(output))
+	}
+	fields := strings.SplitN(strings.TrimSpace(string(output)), ":", 2)
+	if len(fields) != 2 || fields[0] == "" {
+		return nil
+	}
+	if detachOutput, err := exec.Command("losetup", "-d", fields[0]).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to detach loop device for disk %s: %v, output: %s", diskID, err, string(detachOutput))
+	}
+	return nil
+}
+
+// attachLoopDevice finds or creates the loop device backing file, returning
+// its /dev/loopN path.
+func attachLoopDevice(backingFile string) (string, error) {
+	cmd := exec.Command("losetup", "-j", backingFile)
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		fields := strings.SplitN(strings.TrimSpace(string(output)), ":", 2)
+		if len(fields) == 2 && fields[0] != "" {
+			return fields[0], nil
+		}
+	}
+
+	cmd = exec.Command("losetup", "-f", "--show", backingFile)
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("losetup -f %s failed: %v, output: %s", backingFile, err, string(output))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,214 @@
+package linuxloop
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/filesystem"
+)
+
+type filesystemAPI struct {
+	root string
+}
+
+var _ filesystem.API = filesystemAPI{}
+
+func newFilesystemAPI(root string) filesystem.API {
+	return filesystemAPI{root: root}
+}
+
+func (f filesystemAPI) PathExists(path string) (bool, error) {
+	_, err := os.Lstat(confine(f.root, path))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (f filesystemAPI) PathValid(path string) (bool, error) {
+	ok, err := f.PathValidWithTimeout(context.Background(), path, 30*time.Second)
+	if errors.Is(err, filesystem.ErrPathNotFound) || errors.Is(err, filesystem.ErrPathUnreachable) {
+		return false, nil
+	}
+	return ok, err
+}
+
+func (f filesystemAPI) PathValidWithTimeout(ctx context.Context, path string, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := os.Stat(confine(f.root, path))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			return true, nil
+		}
+		if os.IsNotExist(err) {
+			return false, filesystem.ErrPathNotFound
+		}
+		return false, err
+	case <-ctx.Done():
+		return false, fmt.Errorf("%w: path probe for %s timed out: %v", filesystem.ErrPathUnreachable, path, ctx.Err())
+	}
+}
+
+func (f filesystemAPI) Mkdir(path string) error {
+	return os.MkdirAll(confine(f.root, path), 0755)
+}
+
+func (f filesystemAPI) Rmdir(path string, force bool) error {
+	real := confine(f.root, path)
+	if force {
+		return os.RemoveAll(real)
+	}
+	return os.Remove(real)
+}
+
+func (f filesystemAPI) LinkPath(oldname, newname string) error {
+	return os.Symlink(confine(f.root, oldname), confine(f.root, newname))
+}
+
+func (f filesystemAPI) LinkPathSubpath(volumeRoot string, subpath string, target string) error {
+	realRoot := confine(f.root, volumeRoot)
+	if filepath.IsAbs(subpath) {
+		return fmt.Errorf("subpath %q must be a relative path", subpath)
+	}
+
+	joined := filepath.Join(realRoot, subpath)
+	if joined != realRoot && !strings.HasPrefix(joined, realRoot+string(filepath.Separator)) {
+		return fmt.Errorf("subpath %q escapes volume root %q", subpath, volumeRoot)
+	}
+
+	resolved, err := filepath.EvalSymlinks(joined)
+	if err != nil {
+		if os.IsNotExist(err) {
+			resolved = joined
+		} else {
+			return fmt.Errorf("failed to resolve subpath %q: %v", subpath, err)
+		}
+	}
+
+	resolvedRoot, err := filepath.EvalSymlinks(realRoot)
+	if err != nil {
+		return fmt.Errorf("failed to resolve volume root %q: %v", volumeRoot, err)
+	}
+	if resolved != resolvedRoot && !strings.HasPrefix(resolved, resolvedRoot+string(filepath.Separator)) {
+		return fmt.Errorf("subpath %q resolves outside of volume root %q", subpath, volumeRoot)
+	}
+
+	return os.Symlink(resolved, confine(f.root, target))
+}
+
+// IsMountPoint mirrors the Windows implementation's historical semantics: a
+// path counts as a mount point only if it is a symlink whose target exists.
+// Real bind/SMB mounts are classified separately by GetMountPointType.
+func (f filesystemAPI) IsMountPoint(path string) (bool, error) {
+	real := confine(f.root, path)
+	stat, err := os.Lstat(real)
+	if err != nil {
+		return false, err
+	}
+
+	if stat.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(real)
+		if err != nil {
+			return false, fmt.Errorf("readlink error: %v", err)
+		}
+		_, err = os.Lstat(target)
+		if err == nil {
+			return true, nil
+		}
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return false, nil
+}
+
+// GetMountPointType classifies path by consulting /proc/self/mountinfo for
+// an exact match (the Linux equivalent of inspecting a reparse tag on
+// Windows), falling back to a plain symlink check.
+func (f filesystemAPI) GetMountPointType(path string) (filesystem.MountType, error) {
+	real := confine(f.root, path)
+
+	if _, err := os.Lstat(real); err != nil {
+		if os.IsNotExist(err) {
+			return filesystem.MountTypeNone, nil
+		}
+		return filesystem.MountTypeNone, err
+	}
+
+	fsType, mounted, err := lookupMountinfo(real)
+	if err != nil {
+		return filesystem.MountTypeNone, err
+	}
+	if mounted {
+		if ok, _ := f.PathValidWithTimeout(context.Background(), path, 3*time.Second); !ok {
+			return filesystem.MountTypeCorrupted, nil
+		}
+		if strings.Contains(strings.ToLower(fsType), "cifs") || strings.Contains(strings.ToLower(fsType), "smb") {
+			return filesystem.MountTypeSMBRemote, nil
+		}
+		return filesystem.MountTypeVolumeMountPoint, nil
+	}
+
+	stat, err := os.Lstat(real)
+	if err != nil {
+		return filesystem.MountTypeNone, err
+	}
+	if stat.Mode()&os.ModeSymlink != 0 {
+		return filesystem.MountTypeSymlink, nil
+	}
+
+	return filesystem.MountTypeNone, nil
+}
+
+// lookupMountinfo scans /proc/self/mountinfo for an entry whose mount point
+// exactly matches path, returning its filesystem type if found.
+func lookupMountinfo(path string) (fsType string, mounted bool, err error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return "", false, err
+	}
+	defer file.Close()
+
+	target := filepath.Clean(path)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Format: ID parentID major:minor root mountPoint options... - fsType source superOptions
+		fields := strings.Fields(scanner.Text())
+		sep := -1
+		for i, field := range fields {
+			if field == "-" {
+				sep = i
+				break
+			}
+		}
+		if sep < 0 || len(fields) < sep+2 || len(fields) < 5 {
+			continue
+		}
+		if fields[4] == target {
+			return fields[sep+1], true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", false, err
+	}
+	return "", false, nil
+}
@@ -0,0 +1,70 @@
+// Package linuxloop is a development/test stand-in for the real Windows OS
+// backend. It implements the same filesystem.API, smb.API and volume.API
+// surfaces against a chroot-like root directory and loopback files, so
+// contributors without a Windows machine can run the full csi-proxy gRPC
+// server and its integration tests on Linux — the same role the host-path
+// CSI driver plays in place of a real storage backend.
+//
+// Fidelity is deliberately partial: SMB mappings are backed by mount.cifs
+// where available, and "disks" are loop devices backed by regular files
+// rather than physical disks. That is enough to exercise the gRPC contract
+// and server-side logic; it is not a substitute for testing against real
+// Windows hosts before a release.
+package linuxloop
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/backend"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/disk"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/filesystem"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/smb"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/volume"
+)
+
+func init() {
+	backend.Register(backend.LinuxLoop, func() backend.Backend {
+		return New(defaultRoot())
+	})
+}
+
+// defaultRoot is where the linuxloop backend confines all paths when no
+// explicit root has been configured; callers wiring up the server flag
+// should prefer calling New directly with an operator-supplied
+// --os-backend-root, as cmd/csi-proxy does.
+func defaultRoot() string {
+	return filepath.Join("/tmp", "csi-proxy-linuxloop")
+}
+
+// linuxLoopBackend implements backend.Backend against a single root
+// directory shared by all three APIs.
+type linuxLoopBackend struct {
+	root string
+}
+
+// New returns a backend.Backend that confines every path it is given to
+// root. It makes a best-effort attempt to create root (and any missing
+// parents) up front; if that fails (e.g. a read-only --os-backend-root),
+// New still returns a usable Backend and the first API call that touches
+// root will surface the real error.
+func New(root string) backend.Backend {
+	_ = os.MkdirAll(root, 0755)
+	return linuxLoopBackend{root: root}
+}
+
+func (b linuxLoopBackend) Filesystem() filesystem.API { return newFilesystemAPI(b.root) }
+func (b linuxLoopBackend) SMB() smb.API               { return newSMBAPI(b.root) }
+func (b linuxLoopBackend) Volume() volume.API         { return newVolumeAPI(b.root) }
+func (b linuxLoopBackend) Disk() disk.API             { return newDiskAPI(b.root) }
+
+// confine joins path onto root after stripping any leading drive letter or
+// "/" so that callers cannot escape root, the same guarantee
+// filesystem.LinkPathSubpath provides for subPath mounts.
+func confine(root, path string) string {
+	if len(path) >= 2 && path[1] == ':' && 'a' <= path[0]|0x20 && path[0]|0x20 <= 'z' {
+		path = path[2:]
+	}
+	clean := filepath.Clean(string(filepath.Separator) + filepath.ToSlash(path))
+	return filepath.Join(root, clean)
+}
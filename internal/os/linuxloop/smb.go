@@ -0,0 +1,79 @@
+package linuxloop
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/filesystem"
+	"github.com/kubernetes-csi/csi-proxy/internal/os/smb"
+)
+
+type smbAPI struct {
+	root string
+}
+
+var _ smb.API = smbAPI{}
+
+func newSMBAPI(root string) smb.API {
+	return smbAPI{root: root}
+}
+
+// NewSmbGlobalMapping mounts remotePath with mount.cifs, writing the
+// credentials to a private, 0600 temp file instead of argv or the
+// environment so they never show up in a process listing or get logged.
+func (s smbAPI) NewSmbGlobalMapping(remotePath, username, password string, requirePrivacy bool) error {
+	mountPoint := confine(s.root, remotePath)
+	if err := os.MkdirAll(mountPoint, 0755); err != nil {
+		return fmt.Errorf("failed to create mount point for %s: %v", remotePath, err)
+	}
+
+	credFile, err := os.CreateTemp("", "csi-proxy-smb-cred-")
+	if err != nil {
+		return fmt.Errorf("failed to create credentials file: %v", err)
+	}
+	defer os.Remove(credFile.Name())
+	defer credFile.Close()
+
+	if err := credFile.Chmod(0600); err != nil {
+		return fmt.Errorf("failed to secure credentials file: %v", err)
+	}
+	if _, err := fmt.Fprintf(credFile, "username=%s\npassword=%s\n", username, password); err != nil {
+		return fmt.Errorf("failed to write credentials file: %v", err)
+	}
+	if err := credFile.Close(); err != nil {
+		return fmt.Errorf("failed to flush credentials file: %v", err)
+	}
+
+	options := "credentials=" + credFile.Name()
+	if requirePrivacy {
+		options += ",seal"
+	}
+
+	cmd := exec.Command("mount", "-t", "cifs", remotePath, mountPoint, "-o", options)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		// output is intentionally not wrapped with the credentials file
+		// contents; mount.cifs itself never echoes them back.
+		return fmt.Errorf("mount.cifs %s failed: %v, output: %s", remotePath, err, string(output))
+	}
+
+	return nil
+}
+
+func (s smbAPI) RemoveSmbGlobalMapping(remotePath string) error {
+	mountPoint := confine(s.root, remotePath)
+	cmd := exec.Command("umount", mountPoint)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("umount %s failed: %v, output: %s", remotePath, err, string(output))
+	}
+	return nil
+}
+
+func (s smbAPI) IsSmbMappingHealthy(remotePath string) (bool, error) {
+	fs := newFilesystemAPI(s.root).(filesystemAPI)
+	mountType, err := fs.GetMountPointType(remotePath)
+	if err != nil {
+		return false, err
+	}
+	return mountType == filesystem.MountTypeSMBRemote, nil
+}
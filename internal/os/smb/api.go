@@ -0,0 +1,27 @@
+// Package smb implements the SMB OS API calls. All code here should be very
+// simple pass-through to the OS APIs. Any logic around the APIs should go in
+// internal/server/smb/server.go so that logic can be easily unit-tested
+// without requiring specific OS environments.
+//
+// This lets a CSI SMB driver establish a single authenticated connection to
+// \\server\share (a "global mapping", visible to every session on the host)
+// and then use the filesystem API's LinkPath/LinkPathSubpath to bind that
+// share into pod-visible paths, rather than mounting the share once per pod.
+//
+// The platform-neutral API surface lives in this file; the concrete
+// implementation lives in api_windows.go (build tag windows) and
+// api_unsupported.go (every other GOOS).
+package smb
+
+import "time"
+
+// API is the exposed SMB API.
+type API interface {
+	NewSmbGlobalMapping(remotePath, username, password string, requirePrivacy bool) error
+	RemoveSmbGlobalMapping(remotePath string) error
+	IsSmbMappingHealthy(remotePath string) (bool, error)
+}
+
+// healthCheckTimeout bounds how long IsSmbMappingHealthy will wait on the
+// remote share before treating it as stale.
+const healthCheckTimeout = 3 * time.Second
@@ -0,0 +1,33 @@
+//go:build !windows
+
+package smb
+
+import "errors"
+
+// errUnsupported is returned by every method of smbAPI; it exists so the
+// package (and anything that imports its platform-neutral API type, such as
+// internal/os/backend) still builds on non-Windows GOOS values. The real
+// implementation lives in api_windows.go; internal/os/linuxloop provides the
+// non-Windows development backend.
+var errUnsupported = errors.New("smb: not supported on this platform")
+
+type smbAPI struct{}
+
+// check that smbAPI implements API
+var _ API = &smbAPI{}
+
+func New() API {
+	return smbAPI{}
+}
+
+func (smbAPI) NewSmbGlobalMapping(remotePath, username, password string, requirePrivacy bool) error {
+	return errUnsupported
+}
+
+func (smbAPI) RemoveSmbGlobalMapping(remotePath string) error {
+	return errUnsupported
+}
+
+func (smbAPI) IsSmbMappingHealthy(remotePath string) (bool, error) {
+	return false, errUnsupported
+}
@@ -0,0 +1,159 @@
+//go:build windows
+
+package smb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/filesystem"
+)
+
+type smbAPI struct{}
+
+// check that smbAPI implements API
+var _ API = &smbAPI{}
+
+func New() API {
+	return smbAPI{}
+}
+
+// USE_INFO_2, the level-2 structure accepted by NetUseAdd, as documented at
+// https://docs.microsoft.com/en-us/windows/win32/api/lmuse/ns-lmuse-use_info_2
+type useInfo2 struct {
+	ui2LocalName  *uint16
+	ui2RemoteName *uint16
+	ui2Password   *uint16
+	ui2Status     uint32
+	ui2AsgType    uint32
+	ui2RefCount   uint32
+	ui2UseCount   uint32
+	ui2UserName   *uint16
+	ui2Domainname *uint16
+}
+
+// NewSmbGlobalMapping establishes a single, host-wide authenticated
+// connection to remotePath (a \\server\share UNC path) via NetUseAdd, so
+// that every pod on the node can share the one connection instead of each
+// mounting the share separately. password is passed to the Win32 API and is
+// never logged or included in any returned error.
+//
+// requirePrivacy (SMB encryption) cannot be expressed through NetUseAdd:
+// USE_INFO_2 has no encryption/privacy member, and the "use force level"
+// some implementations smuggle data through controls forced disconnect
+// behavior (NOFORCE/FORCE/LOCKFORCE), not encryption. Only the
+// New-SmbGlobalMapping cmdlet (via its WMI provider) can request
+// encryption, so until this is rewritten against that API, a caller asking
+// for requirePrivacy gets an explicit error instead of a silently
+// unencrypted mount.
+func (smbAPI) NewSmbGlobalMapping(remotePath, username, password string, requirePrivacy bool) error {
+	if requirePrivacy {
+		return fmt.Errorf("NewSmbGlobalMapping(%s): requirePrivacy is not supported by NetUseAdd", remotePath)
+	}
+
+	remotePathPtr, err := windows.UTF16PtrFromString(remotePath)
+	if err != nil {
+		return fmt.Errorf("invalid remote path %q: %v", remotePath, err)
+	}
+
+	info := useInfo2{
+		ui2RemoteName: remotePathPtr,
+	}
+
+	if username != "" {
+		usernamePtr, err := windows.UTF16PtrFromString(username)
+		if err != nil {
+			return fmt.Errorf("invalid username: %v", err)
+		}
+		info.ui2UserName = usernamePtr
+	}
+
+	if password != "" {
+		passwordPtr, err := windows.UTF16PtrFromString(password)
+		if err != nil {
+			return fmt.Errorf("invalid password: %v", err)
+		}
+		info.ui2Password = passwordPtr
+	}
+
+	if err := netUseAdd(&info); err != nil {
+		// password is intentionally omitted from this error.
+		return fmt.Errorf("NetUseAdd(%s) failed: %v", remotePath, err)
+	}
+
+	return nil
+}
+
+// RemoveSmbGlobalMapping tears down the global mapping to remotePath
+// previously established by NewSmbGlobalMapping.
+func (smbAPI) RemoveSmbGlobalMapping(remotePath string) error {
+	remotePathPtr, err := windows.UTF16PtrFromString(remotePath)
+	if err != nil {
+		return fmt.Errorf("invalid remote path %q: %v", remotePath, err)
+	}
+
+	if err := netUseDel(remotePathPtr); err != nil {
+		return fmt.Errorf("NetUseDel(%s) failed: %v", remotePath, err)
+	}
+
+	return nil
+}
+
+// IsSmbMappingHealthy reports whether remotePath is still reachable with the
+// bounded CreateFile probe of filesystem.PathValidWithTimeout; an
+// unreachable or missing share is reported as false, and any other probe
+// error is returned. This catches the common failure mode where the mapping
+// still exists but the SMB password has rotated server-side and the
+// connection is now being refused.
+func (smbAPI) IsSmbMappingHealthy(remotePath string) (bool, error) {
+	ok, err := filesystem.New().PathValidWithTimeout(context.Background(), remotePath, healthCheckTimeout)
+	if errors.Is(err, filesystem.ErrPathUnreachable) || errors.Is(err, filesystem.ErrPathNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+var (
+	modnetapi32   = windows.NewLazySystemDLL("netapi32.dll")
+	procNetUseAdd = modnetapi32.NewProc("NetUseAdd")
+	procNetUseDel = modnetapi32.NewProc("NetUseDel")
+)
+
+// netUseAdd calls NetUseAdd at USE_INFO_2 level, the same level used by
+// New-SmbGlobalMapping under the hood.
+// https://docs.microsoft.com/en-us/windows/win32/api/lmuse/nf-lmuse-netuseadd
+func netUseAdd(info *useInfo2) error {
+	ret, _, _ := procNetUseAdd.Call(
+		0, // servername: NULL means the local computer
+		2, // level
+		uintptr(unsafe.Pointer(info)),
+		0, // parm_err: not needed by callers
+	)
+	if ret != 0 {
+		return fmt.Errorf("netapi32 error %d", ret)
+	}
+	return nil
+}
+
+// netUseDel calls NetUseDel, forcing disconnection of any open resources on
+// the connection (mirroring Remove-SmbGlobalMapping -Force).
+// https://docs.microsoft.com/en-us/windows/win32/api/lmuse/nf-lmuse-netusedel
+func netUseDel(remoteName *uint16) error {
+	const useForceDelete = 2
+	ret, _, _ := procNetUseDel.Call(
+		0, // usename: NULL means the local computer
+		uintptr(unsafe.Pointer(remoteName)),
+		useForceDelete,
+	)
+	if ret != 0 {
+		return fmt.Errorf("netapi32 error %d", ret)
+	}
+	return nil
+}
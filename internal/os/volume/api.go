@@ -0,0 +1,138 @@
+package volume
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+)
+
+// Implements the Volume OS API calls. All code here should be very simple
+// pass-through to the OS APIs. Any logic around the APIs should go in
+// internal/server/volume/server.go so that logic can be easily unit-tested
+// without requiring specific OS environments.
+
+// API is the exposed Volume API
+type API interface {
+	FormatVolume(volumeID string, fsType string) error
+	FormatVolumeWithProgress(ctx context.Context, volumeID string, fsType string, progress func(FormatProgress)) error
+}
+
+// FormatProgress is a single progress update emitted while a volume is being
+// formatted, carrying enough information for a caller to report it upstream
+// as a CSI long-running-operation event.
+type FormatProgress struct {
+	// Percent is Format-Volume's own progress percentage, 0-100.
+	Percent int32
+	// Stage is a short human-readable description of the current step,
+	// e.g. "Initializing disk" or "Creating file system".
+	Stage string
+	// BytesWritten is the running total of bytes written so far, where
+	// known; 0 if the underlying cmdlet does not report it.
+	BytesWritten int64
+}
+
+type volumeAPI struct{}
+
+// check that volumeAPI implements API
+var _ API = &volumeAPI{}
+
+func New() API {
+	return volumeAPI{}
+}
+
+// FormatVolume formats volumeID with fsType using Format-Volume and waits
+// for it to complete.
+func (volumeAPI) FormatVolume(volumeID string, fsType string) error {
+	cmd := exec.Command("powershell", "/c",
+		fmt.Sprintf(`Get-Volume -UniqueId "%s" | Format-Volume -FileSystem %s -Confirm:$false`, volumeID, fsType))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to format volume %s: %v, output: %s", volumeID, err, string(output))
+	}
+	return nil
+}
+
+// formatProgressPattern matches the "PROGRESS <percent> <status>" lines
+// formatProgressScript prints after polling Format-Volume's progress stream
+// (Write-Progress), e.g. "PROGRESS 42 Creating file system".
+var formatProgressPattern = regexp.MustCompile(`^PROGRESS (\d+) ?(.*)$`)
+
+// formatProgressScript runs Format-Volume as a background job so its
+// progress stream (populated by Write-Progress, which isn't one of the
+// streams a basic n>&1 redirection can capture) can be polled via
+// $job.ChildJobs[0].Progress and relayed to our stdout as plain text lines,
+// one per progress record, for the parent process to parse.
+const formatProgressScript = `
+$ErrorActionPreference = 'Stop'
+$job = Start-Job -ScriptBlock {
+    param($UniqueId, $FileSystem)
+    Get-Volume -UniqueId $UniqueId | Format-Volume -FileSystem $FileSystem -Confirm:$false
+} -ArgumentList $Env:csiproxy_volume_id, $Env:csiproxy_fs_type
+
+try {
+    while ($job.State -eq 'Running') {
+        foreach ($p in @($job.ChildJobs[0].Progress.ReadAll())) {
+            Write-Output ("PROGRESS {0} {1}" -f $p.PercentComplete, $p.StatusDescription)
+        }
+        Start-Sleep -Milliseconds 250
+    }
+    foreach ($p in @($job.ChildJobs[0].Progress.ReadAll())) {
+        Write-Output ("PROGRESS {0} {1}" -f $p.PercentComplete, $p.StatusDescription)
+    }
+    Receive-Job -Job $job -Wait -AutoRemoveJob
+} finally {
+    Remove-Job -Job $job -Force -ErrorAction SilentlyContinue
+}
+`
+
+// FormatVolumeWithProgress formats volumeID with fsType, invoking progress
+// with a FormatProgress update for every percentage Format-Volume reports on
+// its progress stream, and aborting the underlying PowerShell job if ctx is
+// cancelled. Formatting a multi-TB NTFS volume can take many minutes, so
+// this gives callers visibility and a way to time out cleanly instead of
+// blocking on a single unary call.
+func (volumeAPI) FormatVolumeWithProgress(ctx context.Context, volumeID string, fsType string, progress func(FormatProgress)) error {
+	cmd := exec.CommandContext(ctx, "powershell", "/c", formatProgressScript)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("csiproxy_volume_id=%s", volumeID),
+		fmt.Sprintf("csiproxy_fs_type=%s", fsType))
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stdout pipe for formatting volume %s: %v", volumeID, err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start formatting volume %s: %v", volumeID, err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := formatProgressPattern.FindStringSubmatch(line); match != nil {
+			percent, err := strconv.Atoi(match[1])
+			if err != nil {
+				continue
+			}
+			if progress != nil {
+				progress(FormatProgress{
+					Percent: int32(percent),
+					Stage:   match[2],
+				})
+			}
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("formatting volume %s cancelled: %v", volumeID, ctx.Err())
+		}
+		return fmt.Errorf("failed to format volume %s: %v", volumeID, err)
+	}
+
+	return nil
+}
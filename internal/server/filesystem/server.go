@@ -0,0 +1,127 @@
+// Package filesystem holds the logic around internal/os/filesystem.API that
+// doesn't belong in the OS layer itself (request validation, translating
+// error taxonomies into a response shape), so it can be unit-tested without
+// a specific OS environment, per the convention documented on
+// filesystem.API itself.
+//
+// Server's methods are deliberately scoped to that OS-API-facing logic
+// only: this tree has no client/api/filesystem/v1 proto definitions and no
+// google.golang.org/grpc dependency to register a service on, so there is
+// no grpc.Server wiring here. A caller adding the v1 proto package can
+// generate the real request/response messages from LinkPathSubpathRequest,
+// GetMountPointTypeRequest and PathValidWithTimeoutRequest below and have
+// Server's methods satisfy the generated service interface directly, since
+// they already take a context.Context and return (*Response, error).
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/filesystem"
+)
+
+// Server implements the filesystem API group on top of a filesystem.API.
+type Server struct {
+	api filesystem.API
+}
+
+// New returns a Server that serves the filesystem API group from api.
+func New(api filesystem.API) *Server {
+	return &Server{api: api}
+}
+
+// LinkPathSubpathRequest requests that a symlink be created at Target
+// pointing at the directory Subpath resolves to under VolumeRoot.
+type LinkPathSubpathRequest struct {
+	VolumeRoot string
+	Subpath    string
+	Target     string
+}
+
+// LinkPathSubpathResponse is returned once the symlink has been created.
+type LinkPathSubpathResponse struct{}
+
+// LinkPathSubpath creates Target as a symlink to the directory at
+// Subpath, a path relative to VolumeRoot, so a CSI driver can implement
+// volumeMounts.subPath-style publishing. Path-escape validation (rejecting
+// absolute paths, ".." traversal and symlink escapes) happens in
+// internal/os/filesystem; this handler's job is just request/response
+// translation so it stays thin as the gRPC surface grows.
+func (s *Server) LinkPathSubpath(ctx context.Context, request *LinkPathSubpathRequest) (*LinkPathSubpathResponse, error) {
+	if err := s.api.LinkPathSubpath(request.VolumeRoot, request.Subpath, request.Target); err != nil {
+		return nil, err
+	}
+	return &LinkPathSubpathResponse{}, nil
+}
+
+// GetMountPointTypeRequest requests the MountType of Path.
+type GetMountPointTypeRequest struct {
+	Path string
+}
+
+// GetMountPointTypeResponse carries the MountType found at the requested
+// path, e.g. so a kubelet-side caller can tell a real Windows volume mount
+// point apart from a plain symlink or a stale/corrupted SMB mount, and
+// decide whether a remount is needed.
+type GetMountPointTypeResponse struct {
+	MountType filesystem.MountType
+}
+
+// GetMountPointType reports the MountType of request.Path.
+func (s *Server) GetMountPointType(ctx context.Context, request *GetMountPointTypeRequest) (*GetMountPointTypeResponse, error) {
+	mountType, err := s.api.GetMountPointType(request.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &GetMountPointTypeResponse{MountType: mountType}, nil
+}
+
+// PathValidReason classifies why PathValidWithTimeout's Valid came back
+// false, so a kubelet-side caller can tell "needs a remount" apart from
+// "needs to be recreated" without string-matching an error message.
+type PathValidReason string
+
+const (
+	// PathValidReasonNone means Valid is true.
+	PathValidReasonNone PathValidReason = ""
+	// PathValidReasonUnreachable means the server backing path (e.g. an
+	// SMB share) could not be reached within the timeout.
+	PathValidReasonUnreachable PathValidReason = "Unreachable"
+	// PathValidReasonNotFound means every element of path was reachable
+	// but the path itself does not exist.
+	PathValidReasonNotFound PathValidReason = "NotFound"
+)
+
+// PathValidWithTimeoutRequest requests whether Path exists and is
+// reachable, bounded by Timeout.
+type PathValidWithTimeoutRequest struct {
+	Path    string
+	Timeout time.Duration
+}
+
+// PathValidWithTimeoutResponse carries both the historical boolean result
+// and, when Valid is false, a Reason distinguishing an unreachable remote
+// server from a path that genuinely doesn't exist.
+type PathValidWithTimeoutResponse struct {
+	Valid  bool
+	Reason PathValidReason
+}
+
+// PathValidWithTimeout reports whether request.Path exists and is
+// reachable, classifying a false result via Reason so callers can decide
+// whether to remount (Unreachable) or recreate (NotFound) the path.
+func (s *Server) PathValidWithTimeout(ctx context.Context, request *PathValidWithTimeoutRequest) (*PathValidWithTimeoutResponse, error) {
+	ok, err := s.api.PathValidWithTimeout(ctx, request.Path, request.Timeout)
+	switch {
+	case err == nil:
+		return &PathValidWithTimeoutResponse{Valid: ok}, nil
+	case errors.Is(err, filesystem.ErrPathUnreachable):
+		return &PathValidWithTimeoutResponse{Valid: false, Reason: PathValidReasonUnreachable}, nil
+	case errors.Is(err, filesystem.ErrPathNotFound):
+		return &PathValidWithTimeoutResponse{Valid: false, Reason: PathValidReasonNotFound}, nil
+	default:
+		return nil, err
+	}
+}
@@ -0,0 +1,95 @@
+// Package smb wraps internal/os/smb.API with the request/response shapes a
+// CSI SMB driver calls through. There is no client/api/smb/v1 proto package
+// or google.golang.org/grpc dependency in this tree yet, so Server is not
+// registered on a grpc.Server; NewSmbGlobalMappingRequest redacts Password
+// in its own String method in the meantime, the same property a proto
+// field marked sensitive would give the generated stringer once that
+// package exists.
+package smb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/smb"
+)
+
+// Server implements the smb API group on top of an smb.API.
+type Server struct {
+	api smb.API
+}
+
+// New returns a Server that serves the smb API group from api.
+func New(api smb.API) *Server {
+	return &Server{api: api}
+}
+
+// NewSmbGlobalMappingRequest requests a host-wide authenticated mapping to
+// RemotePath be established.
+type NewSmbGlobalMappingRequest struct {
+	RemotePath     string
+	Username       string
+	Password       string
+	RequirePrivacy bool
+}
+
+// String redacts Password so a logged or printed request never leaks the
+// credential, mirroring what a proto-generated stringer would do for a
+// field marked sensitive.
+func (r *NewSmbGlobalMappingRequest) String() string {
+	return fmt.Sprintf("NewSmbGlobalMappingRequest{RemotePath:%q, Username:%q, Password:\"***\", RequirePrivacy:%t}",
+		r.RemotePath, r.Username, r.RequirePrivacy)
+}
+
+// NewSmbGlobalMappingResponse is returned once the mapping is established.
+type NewSmbGlobalMappingResponse struct{}
+
+// NewSmbGlobalMapping establishes a host-wide authenticated connection to
+// request.RemotePath, so every pod on the node can share it instead of each
+// mounting the share separately.
+func (s *Server) NewSmbGlobalMapping(ctx context.Context, request *NewSmbGlobalMappingRequest) (*NewSmbGlobalMappingResponse, error) {
+	if err := s.api.NewSmbGlobalMapping(request.RemotePath, request.Username, request.Password, request.RequirePrivacy); err != nil {
+		return nil, err
+	}
+	return &NewSmbGlobalMappingResponse{}, nil
+}
+
+// RemoveSmbGlobalMappingRequest requests that the mapping to RemotePath
+// previously established by NewSmbGlobalMapping be torn down.
+type RemoveSmbGlobalMappingRequest struct {
+	RemotePath string
+}
+
+// RemoveSmbGlobalMappingResponse is returned once the mapping is removed.
+type RemoveSmbGlobalMappingResponse struct{}
+
+// RemoveSmbGlobalMapping tears down the mapping to request.RemotePath.
+func (s *Server) RemoveSmbGlobalMapping(ctx context.Context, request *RemoveSmbGlobalMappingRequest) (*RemoveSmbGlobalMappingResponse, error) {
+	if err := s.api.RemoveSmbGlobalMapping(request.RemotePath); err != nil {
+		return nil, err
+	}
+	return &RemoveSmbGlobalMappingResponse{}, nil
+}
+
+// IsSmbMappingHealthyRequest requests the health of the mapping to
+// RemotePath.
+type IsSmbMappingHealthyRequest struct {
+	RemotePath string
+}
+
+// IsSmbMappingHealthyResponse reports whether the mapping is still usable,
+// e.g. false after the remote share's credentials have rotated.
+type IsSmbMappingHealthyResponse struct {
+	Healthy bool
+}
+
+// IsSmbMappingHealthy reports whether the mapping to request.RemotePath is
+// still usable, so a caller can detect a stale mapping (e.g. after an SMB
+// password rotation) and trigger a remount.
+func (s *Server) IsSmbMappingHealthy(ctx context.Context, request *IsSmbMappingHealthyRequest) (*IsSmbMappingHealthyResponse, error) {
+	healthy, err := s.api.IsSmbMappingHealthy(request.RemotePath)
+	if err != nil {
+		return nil, err
+	}
+	return &IsSmbMappingHealthyResponse{Healthy: healthy}, nil
+}
@@ -0,0 +1,64 @@
+// Package volume wraps internal/os/volume.API with the request/response
+// shapes a CSI driver calls through, including the fan-out from a single
+// progress callback (FormatVolumeWithProgress) to a stream of responses
+// (FormatVolumeStream). Lacking a google.golang.org/grpc dependency or a
+// client/api/volume/v1 proto package in this tree, FormatVolumeStream takes
+// a plain send func in place of a generated stream's Send method, and isn't
+// registered on a grpc.Server or exercised by an integration test against a
+// real streaming transport — see server_test.go for the coverage that is
+// possible without one.
+package volume
+
+import (
+	"context"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/volume"
+)
+
+// Server implements the volume API group on top of a volume.API.
+type Server struct {
+	api volume.API
+}
+
+// New returns a Server that serves the volume API group from api.
+func New(api volume.API) *Server {
+	return &Server{api: api}
+}
+
+// FormatVolumeStreamRequest requests that VolumeID be formatted with
+// FsType, streaming progress back to the caller.
+type FormatVolumeStreamRequest struct {
+	VolumeID string
+	FsType   string
+}
+
+// FormatVolumeStreamResponse is one progress update in a FormatVolumeStream
+// response stream.
+type FormatVolumeStreamResponse struct {
+	Percent      int32
+	Stage        string
+	BytesWritten int64
+}
+
+// FormatVolumeStream formats request.VolumeID with request.FsType, calling
+// send with a FormatVolumeStreamResponse for every progress update reported
+// by the underlying format job, and aborting it if ctx is cancelled. This
+// gives a caller visibility and a way to time out cleanly on a multi-TB NTFS
+// format instead of blocking on a single unary call with no progress.
+func (s *Server) FormatVolumeStream(ctx context.Context, request *FormatVolumeStreamRequest, send func(*FormatVolumeStreamResponse) error) error {
+	var sendErr error
+	err := s.api.FormatVolumeWithProgress(ctx, request.VolumeID, request.FsType, func(p volume.FormatProgress) {
+		if sendErr != nil {
+			return
+		}
+		sendErr = send(&FormatVolumeStreamResponse{
+			Percent:      p.Percent,
+			Stage:        p.Stage,
+			BytesWritten: p.BytesWritten,
+		})
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package volume
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kubernetes-csi/csi-proxy/internal/os/volume"
+)
+
+// fakeVolumeAPI lets FormatVolumeStream be tested without a real OS backend.
+type fakeVolumeAPI struct {
+	progress []volume.FormatProgress
+	err      error
+}
+
+var _ volume.API = &fakeVolumeAPI{}
+
+func (f *fakeVolumeAPI) FormatVolume(volumeID string, fsType string) error {
+	return errors.New("not used by this test")
+}
+
+func (f *fakeVolumeAPI) FormatVolumeWithProgress(ctx context.Context, volumeID string, fsType string, progress func(volume.FormatProgress)) error {
+	for _, p := range f.progress {
+		if progress != nil {
+			progress(p)
+		}
+	}
+	return f.err
+}
+
+func TestFormatVolumeStreamRelaysProgress(t *testing.T) {
+	api := &fakeVolumeAPI{
+		progress: []volume.FormatProgress{
+			{Percent: 0, Stage: "Formatting"},
+			{Percent: 100, Stage: "Complete"},
+		},
+	}
+	s := New(api)
+
+	var got []*FormatVolumeStreamResponse
+	err := s.FormatVolumeStream(context.Background(), &FormatVolumeStreamRequest{VolumeID: "disk0", FsType: "ntfs"}, func(r *FormatVolumeStreamResponse) error {
+		got = append(got, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FormatVolumeStream returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d responses, want 2", len(got))
+	}
+	if got[0].Percent != 0 || got[0].Stage != "Formatting" {
+		t.Errorf("first response = %+v, want Percent=0 Stage=Formatting", got[0])
+	}
+	if got[1].Percent != 100 || got[1].Stage != "Complete" {
+		t.Errorf("second response = %+v, want Percent=100 Stage=Complete", got[1])
+	}
+}
+
+func TestFormatVolumeStreamStopsOnSendError(t *testing.T) {
+	api := &fakeVolumeAPI{
+		progress: []volume.FormatProgress{
+			{Percent: 0, Stage: "Formatting"},
+			{Percent: 50, Stage: "Writing"},
+			{Percent: 100, Stage: "Complete"},
+		},
+	}
+	s := New(api)
+
+	sendErr := errors.New("client disconnected")
+	calls := 0
+	err := s.FormatVolumeStream(context.Background(), &FormatVolumeStreamRequest{VolumeID: "disk0", FsType: "ntfs"}, func(r *FormatVolumeStreamResponse) error {
+		calls++
+		return sendErr
+	})
+	if !errors.Is(err, sendErr) {
+		t.Fatalf("FormatVolumeStream error = %v, want %v", err, sendErr)
+	}
+	if calls != 1 {
+		t.Errorf("send was called %d times after failing once, want 1", calls)
+	}
+}
+
+func TestFormatVolumeStreamPropagatesFormatError(t *testing.T) {
+	formatErr := errors.New("format failed")
+	api := &fakeVolumeAPI{err: formatErr}
+	s := New(api)
+
+	err := s.FormatVolumeStream(context.Background(), &FormatVolumeStreamRequest{VolumeID: "disk0", FsType: "ntfs"}, func(r *FormatVolumeStreamResponse) error {
+		return nil
+	})
+	if !errors.Is(err, formatErr) {
+		t.Fatalf("FormatVolumeStream error = %v, want %v", err, formatErr)
+	}
+}